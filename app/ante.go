@@ -0,0 +1,93 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app
+
+import (
+	circuitante "cosmossdk.io/x/circuit/ante"
+	circuitkeeper "cosmossdk.io/x/circuit/keeper"
+	errorsmod "cosmossdk.io/errors"
+
+	wasmkeeper "github.com/CosmWasm/wasmd/x/wasm/keeper"
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+	groupkeeper "github.com/cosmos/cosmos-sdk/x/group/keeper"
+	ibcante "github.com/cosmos/ibc-go/v8/modules/core/ante"
+	ibckeeper "github.com/cosmos/ibc-go/v8/modules/core/keeper"
+)
+
+// HandlerOptions extends the SDK's ante.HandlerOptions with the keepers and
+// config persistenceCore's own decorators need.
+type HandlerOptions struct {
+	ante.HandlerOptions
+
+	IBCKeeper         *ibckeeper.Keeper
+	WasmConfig        *wasmtypes.WasmConfig
+	TXCounterStoreKey storetypes.StoreKey
+	CircuitKeeper     *circuitkeeper.Keeper
+	GroupKeeper       groupkeeper.Keeper
+}
+
+// NewAnteHandler builds the persistenceCore ante decorator chain.
+func NewAnteHandler(options HandlerOptions) (sdk.AnteHandler, error) {
+	if options.AccountKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "account keeper is required for ante builder")
+	}
+	if options.BankKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "bank keeper is required for ante builder")
+	}
+	if options.SignModeHandler == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "sign mode handler is required for ante builder")
+	}
+	if options.WasmConfig == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "wasm config is required for ante builder")
+	}
+	if options.TXCounterStoreKey == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "tx counter store key is required for ante builder")
+	}
+	if options.IBCKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "ibc keeper is required for ante builder")
+	}
+
+	sigGasConsumer := options.SigGasConsumer
+	if sigGasConsumer == nil {
+		sigGasConsumer = ante.DefaultSigVerificationGasConsumer
+	}
+
+	anteDecorators := []sdk.AnteDecorator{
+		ante.NewSetUpContextDecorator(),
+		// Reject disabled sdk.Msg URLs before any gas/fee/signature work is
+		// done on them, so governance (or the permissioned committee that
+		// holds the circuit breaker authority) can pause e.g. MsgTransfer or
+		// wasm MsgExecuteContract during an incident without halting the
+		// chain.
+		circuitante.NewCircuitBreakerDecorator(options.CircuitKeeper),
+		// Group proposals carry their messages inside MsgSubmitProposal's
+		// Messages field (or, for MsgExec, reference them by proposal ID),
+		// which the decorator above never unwraps; guard it separately so a
+		// paused message type can't re-enter through a group policy
+		// decision.
+		NewGroupProposalCircuitBreakerDecorator(options.CircuitKeeper, options.GroupKeeper),
+		wasmkeeper.NewLimitSimulationGasDecorator(options.WasmConfig.SimulationGasLimit),
+		wasmkeeper.NewCountTXDecorator(options.TXCounterStoreKey),
+		ante.NewExtensionOptionsDecorator(options.ExtensionOptionChecker),
+		ante.NewValidateBasicDecorator(),
+		ante.NewTxTimeoutHeightDecorator(),
+		ante.NewValidateMemoDecorator(options.AccountKeeper),
+		ante.NewConsumeGasForTxSizeDecorator(options.AccountKeeper),
+		ante.NewDeductFeeDecorator(options.AccountKeeper, options.BankKeeper, options.FeegrantKeeper, options.TxFeeChecker),
+		ante.NewSetPubKeyDecorator(options.AccountKeeper),
+		ante.NewValidateSigCountDecorator(options.AccountKeeper),
+		ante.NewSigGasConsumeDecorator(options.AccountKeeper, sigGasConsumer),
+		ante.NewSigVerificationDecorator(options.AccountKeeper, options.SignModeHandler),
+		ante.NewIncrementSequenceDecorator(options.AccountKeeper),
+		ibcante.NewRedundantRelayDecorator(options.IBCKeeper),
+	}
+
+	return sdk.ChainAnteDecorators(anteDecorators...), nil
+}
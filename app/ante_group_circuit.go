@@ -0,0 +1,74 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app
+
+import (
+	errorsmod "cosmossdk.io/errors"
+	circuitkeeper "cosmossdk.io/x/circuit/keeper"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	groupkeeper "github.com/cosmos/cosmos-sdk/x/group/keeper"
+)
+
+// ErrGroupProposalMsgCircuited is returned when a group proposal embeds a
+// message type the circuit breaker has disabled.
+var ErrGroupProposalMsgCircuited = errorsmod.Register("app", 3, "group proposal contains a circuit-broken message type")
+
+// GroupProposalCircuitBreakerDecorator rejects group.MsgSubmitProposal and
+// group.MsgExec transactions that embed (or, for MsgExec, reference a stored
+// proposal that embeds) a message type the x/circuit breaker currently has
+// disabled, so a paused message type can't re-enter execution via a group
+// policy decision while the circuit breaker is tripped.
+// circuitante.NewCircuitBreakerDecorator only inspects the top-level tx
+// messages, so it never sees these nested ones.
+type GroupProposalCircuitBreakerDecorator struct {
+	circuitKeeper *circuitkeeper.Keeper
+	groupKeeper   groupkeeper.Keeper
+}
+
+func NewGroupProposalCircuitBreakerDecorator(circuitKeeper *circuitkeeper.Keeper, groupKeeper groupkeeper.Keeper) GroupProposalCircuitBreakerDecorator {
+	return GroupProposalCircuitBreakerDecorator{circuitKeeper: circuitKeeper, groupKeeper: groupKeeper}
+}
+
+func (decorator GroupProposalCircuitBreakerDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	for _, msg := range tx.GetMsgs() {
+		var innerMsgs []sdk.Msg
+
+		switch msg := msg.(type) {
+		case *group.MsgSubmitProposal:
+			var err error
+			innerMsgs, err = msg.GetMsgs()
+			if err != nil {
+				return ctx, errorsmod.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+			}
+		case *group.MsgExec:
+			response, err := decorator.groupKeeper.Proposal(ctx, &group.QueryProposalRequest{ProposalId: msg.ProposalId})
+			if err != nil {
+				return ctx, errorsmod.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+			}
+			innerMsgs, err = response.Proposal.GetMsgs()
+			if err != nil {
+				return ctx, errorsmod.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+			}
+		default:
+			continue
+		}
+
+		for _, innerMsg := range innerMsgs {
+			allowed, err := decorator.circuitKeeper.IsAllowed(ctx, sdk.MsgTypeURL(innerMsg))
+			if err != nil {
+				return ctx, err
+			}
+			if !allowed {
+				return ctx, errorsmod.Wrapf(ErrGroupProposalMsgCircuited, "message type %s is currently disabled", sdk.MsgTypeURL(innerMsg))
+			}
+		}
+	}
+
+	return next(ctx, tx, simulate)
+}
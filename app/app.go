@@ -17,6 +17,11 @@ import (
 
 	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
 	reflectionv1 "cosmossdk.io/api/cosmos/reflection/v1"
+	"cosmossdk.io/core/comet"
+	"cosmossdk.io/core/header"
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
 	"github.com/spf13/cast"
 
 	"github.com/CosmWasm/wasmd/x/wasm"
@@ -48,19 +53,32 @@ import (
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	"github.com/cosmos/cosmos-sdk/x/crisis"
 	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
-	upgradetypes "github.com/cosmos/cosmos-sdk/x/upgrade/types"
 	"github.com/gorilla/mux"
 	"github.com/rakyll/statik/fs"
-
-	"github.com/persistenceOne/persistenceCore/v8/app/keepers"
-	"github.com/persistenceOne/persistenceCore/v8/app/upgrades"
-	v8 "github.com/persistenceOne/persistenceCore/v8/app/upgrades/v8"
+	"github.com/spf13/viper"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/keepers"
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+	v10 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v10"
+	v11 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v11"
+	v12 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v12"
+	v13 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v13"
+	v14 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v14"
+	v8 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v8"
+	"github.com/persistenceOne/persistenceCore/v10/streaming"
 )
 
 var (
 	DefaultNodeHome string
-	Upgrades        = []upgrades.Upgrade{v8.Upgrade}
-	ModuleBasics    = module.NewBasicManager(keepers.AppModuleBasics...)
+	// v11 (ChainModeConsumer only) is registered on every binary so its
+	// handler is available the moment governance submits the plan; a
+	// sovereign-mode network simply never schedules it.
+	Upgrades = []upgrades.Upgrade{v8.Upgrade, v10.Upgrade, v11.Upgrade, v12.Upgrade, v13.Upgrade, v14.Upgrade}
+	// ModuleBasics is assembled from keepers.AppModuleBasics, which as of v12
+	// includes group.AppModuleBasic{} so DAOs/committees can hold pstake and
+	// wasm admin rights as group policies instead of single keys, and as of
+	// v13 includes circuit.AppModuleBasic{} for the circuit breaker.
+	ModuleBasics = module.NewBasicManager(keepers.AppModuleBasics...)
 )
 
 var (
@@ -73,6 +91,39 @@ var (
 	EnableSpecificProposals = ""
 )
 
+// ChainMode selects whether the binary runs its own sovereign validator set
+// or delegates block production security to a provider chain over Interchain
+// Security (ICS).
+type ChainMode string
+
+const (
+	// ChainModeSovereign is the default: persistenceCore runs x/staking and
+	// produces its own validator set, as it always has.
+	ChainModeSovereign ChainMode = "sovereign"
+	// ChainModeConsumer runs persistenceCore as a partial-set ICS consumer of
+	// the Cosmos Hub: x/ccv/consumer drives validator set updates from the
+	// provider chain's CCV packets instead of the local staking EndBlocker,
+	// while the LSM x/staking fork stays mounted for pstake's liquid-staking
+	// bookkeeping only.
+	ChainModeConsumer ChainMode = "consumer"
+
+	// FlagChainMode is the `--chain-mode` CLI / app.toml key read by
+	// cmd/persistenceCore and forwarded into NewApplication.
+	FlagChainMode = "chain-mode"
+)
+
+// ICQAllowedQueryPaths is the allow-list of gRPC query paths for the
+// async-icq host module (mounted on a dedicated IBC port in app/keepers): a
+// query packet relayed in from a counterparty chain whose path is not on this
+// list is rejected. wasmbinding.ICQQuerier only reports the host's current
+// allow-list to contracts; it does not submit queries.
+var ICQAllowedQueryPaths = []string{
+	"/cosmos.staking.v1beta1.Query/Validator",
+	"/cosmos.staking.v1beta1.Query/Delegation",
+	"/cosmos.bank.v1beta1.Query/Balance",
+	"/cosmos.distribution.v1beta1.Query/DelegationRewards",
+}
+
 // GetEnabledProposals parses the ProposalsEnabled / EnableSpecificProposals values to
 // produce a list of enabled proposals to pass into wasmd app.
 func GetEnabledProposals() []wasm.ProposalType {
@@ -115,6 +166,11 @@ type Application struct {
 	moduleManager     *module.Manager
 	configurator      module.Configurator
 	simulationManager *module.SimulationManager
+
+	streamingListener *streaming.Listener
+
+	cometService  comet.BlockInfoService
+	headerService header.Service
 }
 
 func NewApplication(
@@ -153,12 +209,18 @@ func NewApplication(
 		panic(fmt.Sprintf("error while reading wasm config: %s", err))
 	}
 
+	chainMode := ChainMode(cast.ToString(applicationOptions.Get(FlagChainMode)))
+	if chainMode == "" {
+		chainMode = ChainModeSovereign
+	}
+
 	app := &Application{
 		BaseApp:           baseApp,
 		legacyAmino:       legacyAmino,
 		applicationCodec:  applicationCodec,
 		interfaceRegistry: interfaceRegistry,
 	}
+	app.registerCoreServices()
 
 	// Setup keepers
 	app.AppKeepers = keepers.NewAppKeeper(
@@ -172,6 +234,9 @@ func NewApplication(
 		enabledProposals,
 		wasmOpts,
 		Bech32MainPrefix,
+		chainMode == ChainModeConsumer,
+		app.cometService,
+		app.headerService,
 	)
 
 	// NOTE: we may consider parsing `appOpts` inside module constructors. For the moment
@@ -186,6 +251,11 @@ func NewApplication(
 	app.moduleManager.SetOrderExportGenesis(orderInitGenesis()...)
 
 	app.moduleManager.RegisterInvariants(app.CrisisKeeper)
+	// Modules built against the v2 module API (appmodule.HasServices) reach
+	// the core/comet and core/header services passed into
+	// keepers.NewAppKeeper above rather than through this configurator, since
+	// this app is wired manually and does not run depinject. No core/branch
+	// service is built or passed.
 	app.configurator = module.NewConfigurator(app.applicationCodec, app.MsgServiceRouter(), app.GRPCQueryRouter())
 	app.moduleManager.RegisterServices(app.configurator)
 
@@ -196,6 +266,7 @@ func NewApplication(
 	app.simulationManager.RegisterStoreDecoders()
 
 	app.registerGRPCServices()
+	app.setupStreaming(applicationOptions)
 
 	app.MountKVStores(app.GetKVStoreKey())
 	app.MountTransientStores(app.GetTransientStoreKey())
@@ -203,11 +274,15 @@ func NewApplication(
 
 	app.setAnteHandler(encodingConfiguration.TransactionConfig, wasmConfig)
 	app.SetInitChainer(app.InitChainer)
+	// ABCI++ 2.0 (cometbft v0.38) splits the legacy BeginBlock/DeliverTx/EndBlock
+	// cycle into PrepareProposal/ProcessProposal (handled by the pob mempool
+	// wiring in cmd/persistenceCore) and a FinalizeBlock that runs PreBlock,
+	// BeginBlock, the message execution, and EndBlock in one ABCI call.
+	app.SetPreBlocker(app.PreBlocker)
 	app.SetBeginBlocker(app.BeginBlocker)
 	app.SetEndBlocker(app.EndBlocker)
 
-	// setup postHandler in this method
-	// app.setupPostHandler()
+	app.setupPostHandler(applicationOptions)
 	app.setupUpgradeHandlers()
 	app.setupUpgradeStoreLoaders()
 
@@ -251,6 +326,8 @@ func (app *Application) setAnteHandler(txConfig client.TxConfig, wasmConfig wasm
 			IBCKeeper:         app.IBCKeeper,
 			WasmConfig:        &wasmConfig,
 			TXCounterStoreKey: app.GetKVStoreKey()[wasm.StoreKey],
+			CircuitKeeper:     &app.CircuitKeeper,
+			GroupKeeper:       app.GroupKeeper,
 		},
 	)
 	if err != nil {
@@ -269,6 +346,33 @@ func (app *Application) registerGRPCServices() {
 	reflectionv1.RegisterReflectionServiceServer(app.GRPCQueryRouter(), reflectionSvc)
 }
 
+// setupStreaming reads the `[streaming]` section of app.toml and, if at
+// least one sink is enabled, registers a streaming.Listener on the BaseApp's
+// StreamingManager so operators can run indexing pipelines like
+// mapofzones/cosmos-watcher without patching the binary.
+func (app *Application) setupStreaming(applicationOptions servertypes.AppOptions) {
+	streamingConfig := streaming.DefaultConfig()
+	if v, ok := applicationOptions.(*viper.Viper); ok {
+		if err := v.UnmarshalKey("streaming", &streamingConfig); err != nil {
+			panic(fmt.Errorf("failed to read [streaming] app.toml section: %s", err))
+		}
+	}
+
+	if len(streamingConfig.Sinks) == 0 {
+		return
+	}
+
+	sinks, err := streaming.NewSinks(streamingConfig, app.applicationCodec, app.Logger())
+	if err != nil {
+		panic(fmt.Errorf("failed to build streaming sinks: %s", err))
+	}
+
+	app.streamingListener = streaming.NewListener(streamingConfig.Filter, sinks)
+	app.SetStreamingManager(storetypes.StreamingManager{
+		ABCIListeners: []storetypes.ABCIListener{app.streamingListener},
+	})
+}
+
 func (app *Application) ApplicationCodec() codec.Codec {
 	return app.applicationCodec
 }
@@ -281,34 +385,40 @@ func (app *Application) LegacyAmino() *codec.LegacyAmino {
 	return app.legacyAmino
 }
 
-func (app *Application) BeginBlocker(ctx sdk.Context, req abcitypes.RequestBeginBlock) abcitypes.ResponseBeginBlock {
-	return app.moduleManager.BeginBlock(ctx, req)
+// PreBlocker runs before BeginBlock as part of FinalizeBlock; it exists so
+// modules (e.g. x/upgrade, x/consensus) can mutate consensus parameters that
+// BeginBlock itself depends on reading.
+func (app *Application) PreBlocker(ctx sdk.Context, req *abcitypes.RequestFinalizeBlock) (*sdk.ResponsePreBlock, error) {
+	return app.moduleManager.PreBlock(ctx)
 }
 
-func (app *Application) EndBlocker(ctx sdk.Context, req abcitypes.RequestEndBlock) abcitypes.ResponseEndBlock {
-	// FIXME(max): remove this block after state migration is final
-	if ctx.BlockHeight() > 11060956 {
-		validators := app.StakingKeeper.GetLastValidators(ctx)
-		for _, val := range validators {
-			var valNeedsUpdate bool
-
-			if val.TotalLiquidShares.IsNil() {
-				val.TotalLiquidShares = sdk.ZeroDec()
-				valNeedsUpdate = true
-			}
-			if val.TotalValidatorBondShares.IsNil() {
-				val.TotalValidatorBondShares = sdk.ZeroDec()
-				valNeedsUpdate = true
-			}
-
-			if valNeedsUpdate {
-				app.StakingKeeper.SetValidator(ctx, val)
-				app.Logger().Info("migrated validator fields for liquid shares", "val", val.OperatorAddress)
-			}
-		}
-	}
+// CometInfoService and HeaderService expose the core/comet and core/header
+// runtime services built in registerCoreServices, so that manually-wired
+// keepers (app/keepers) can pass them to modules written against the v2
+// module API without those modules reaching into the store keys or the ABCI
+// request types directly.
+func (app *Application) CometInfoService() comet.BlockInfoService {
+	return app.cometService
+}
+
+func (app *Application) HeaderService() header.Service {
+	return app.headerService
+}
+
+func (app *Application) BeginBlocker(ctx sdk.Context) (sdk.BeginBlock, error) {
+	return app.moduleManager.BeginBlock(ctx)
+}
 
-	return app.moduleManager.EndBlock(ctx, req)
+func (app *Application) EndBlocker(ctx sdk.Context) (sdk.EndBlock, error) {
+	// In ChainModeConsumer, x/ccv/consumer (mounted ahead of x/staking in
+	// orderEndBlockers) is the module that returns validator-set updates to
+	// CometBFT; the LSM x/staking fork below still runs for pstake's
+	// liquid-staking bookkeeping but no longer drives consensus.
+	//
+	// The one-time TotalLiquidShares/TotalValidatorBondShares backfill that
+	// used to run here on every block past height 11060956 is now a one-shot
+	// migration in app/upgrades/v14; see v14.Upgrade.
+	return app.moduleManager.EndBlock(ctx)
 }
 
 func (app *Application) InitChainer(ctx sdk.Context, req abcitypes.RequestInitChain) abcitypes.ResponseInitChain {
@@ -317,9 +427,16 @@ func (app *Application) InitChainer(ctx sdk.Context, req abcitypes.RequestInitCh
 		panic(err)
 	}
 
-	app.UpgradeKeeper.SetModuleVersionMap(ctx, app.moduleManager.GetVersionMap())
+	if err := app.UpgradeKeeper.SetModuleVersionMap(ctx, app.moduleManager.GetVersionMap()); err != nil {
+		panic(err)
+	}
+
+	response, err := app.moduleManager.InitGenesis(ctx, app.applicationCodec, genesisState)
+	if err != nil {
+		panic(err)
+	}
 
-	return app.moduleManager.InitGenesis(ctx, app.applicationCodec, genesisState)
+	return *response
 }
 
 func (app *Application) ModuleAccountAddrs() map[string]bool {
@@ -348,7 +465,8 @@ func (app *Application) RegisterAPIRoutes(apiServer *api.Server, apiConfig confi
 	tmservice.RegisterGRPCGatewayRoutes(clientCtx, apiServer.GRPCGatewayRouter)
 	// Register node gRPC service for grpc-gateway.
 	nodeservice.RegisterGRPCGatewayRoutes(clientCtx, apiServer.GRPCGatewayRouter)
-	// Register grpc-gateway routes for all modules.
+	// Register grpc-gateway routes for all modules, including x/circuit's
+	// authorized-accounts and disabled-URL-list queries.
 	ModuleBasics.RegisterGRPCGatewayRoutes(clientCtx, apiServer.GRPCGatewayRouter)
 
 	// register swagger API from root so that other applications can override easily
@@ -390,17 +508,24 @@ func (app *Application) setupUpgradeStoreLoaders() {
 }
 
 // PostHandlers are like AnteHandlers (they have the same signature), but they are run after runMsgs.
-// One use case for PostHandlers is transaction tips,
-// but other use cases like unused gas refund can also be enabled by PostHandlers.
+// The only PostDecorator this app currently enables is the unused gas refund;
+// a separate transaction-tip decorator is not possible on this SDK version,
+// since cosmos-sdk dropped tip support (TipTx/GetTip) before v0.50.
 //
 // In baseapp, postHandlers are run in the same store branch as `runMsgs`,
 // meaning that both `runMsgs` and `postHandler` state will be committed if
 // both are successful, and both will be reverted if any of the two fails.
-// nolint:unused // post handle is not used for now (as there is no requirement of it)
-func (app *Application) setupPostHandler() {
+func (app *Application) setupPostHandler(applicationOptions servertypes.AppOptions) {
+	refundFraction := math.LegacyMustNewDecFromStr(DefaultUnusedGasRefundFraction)
+	if v, ok := applicationOptions.(*viper.Viper); ok && v.IsSet("unused-gas-refund-fraction") {
+		refundFraction = math.LegacyMustNewDecFromStr(v.GetString("unused-gas-refund-fraction"))
+	}
+
 	postDecorators := []sdk.PostDecorator{
-		// posthandler.NewTipDecorator(app.BankKeeper),
-		// ... add more decorators as needed
+		// Refunds a fraction of unused gas to the fee payer on a successful
+		// tx; disabled for wasm execute/instantiate/migrate since that gas
+		// is metered inside wasmvm, not by the SDK gas meter this reads.
+		NewUnusedGasRefundDecorator(app.BankKeeper, refundFraction),
 	}
 	postHandler := sdk.ChainPostDecorators(postDecorators...)
 	app.SetPostHandler(postHandler)
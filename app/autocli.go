@@ -0,0 +1,33 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app
+
+import (
+	autocliv1 "cosmossdk.io/api/cosmos/autocli/v1"
+
+	"cosmossdk.io/client/v2/autocli"
+
+	groupmodule "github.com/cosmos/cosmos-sdk/x/group/module"
+)
+
+// AutoCliOpts returns the autocli.AppOptions the root command uses to build
+// its tx/query subcommands. It reuses the module set already registered for
+// gRPC query reflection in registerGRPCServices, so a module only needs to
+// implement appmodule.HasAutoCLIConfig once to get both.
+//
+// group needs its own entry here: MsgSubmitProposal embeds an arbitrary list
+// of sdk.Msg, which autocli cannot derive flags for from its proto
+// definition alone, so persistenceCore falls back to group's own
+// hand-written commands for the proposal-lifecycle messages instead of
+// autocli's generated ones.
+func (app *Application) AutoCliOpts() autocli.AppOptions {
+	return autocli.AppOptions{
+		Modules:               app.moduleManager.Modules,
+		ModuleOptions:         map[string]*autocliv1.ModuleOptions{groupmodule.AppModule{}.Name(): nil},
+		AddressCodec:          app.interfaceRegistry.SigningContext().AddressCodec(),
+		ValidatorAddressCodec: app.interfaceRegistry.SigningContext().ValidatorAddressCodec(),
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app
+
+import (
+	"context"
+
+	"cosmossdk.io/core/comet"
+	"cosmossdk.io/core/header"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// registerCoreServices builds the core/comet and core/header runtime
+// services and stores them on Application; NewApplication passes them to
+// keepers.NewAppKeeper, not to the module configurator, so modules
+// that declare appmodule.HasServices (the v2 module API) can depend on them
+// without reaching into the store keys directly. This is what unblocks
+// gradually migrating Persistence's own modules to the v2 module API
+// alongside wasm, which already consumes these services.
+func (app *Application) registerCoreServices() {
+	app.cometService = cometInfoService{}
+	app.headerService = headerInfoService{}
+}
+
+// cometInfoService implements core/comet.BlockInfoService by reading the
+// comet.BlockInfo baseapp already attaches to the sdk.Context for the
+// current request - the same thing runtime.cometInfoService does for
+// modules built with depinject - so there is nothing to track per-block
+// here.
+type cometInfoService struct{}
+
+var _ comet.BlockInfoService = cometInfoService{}
+
+func (cometInfoService) GetCometBlockInfo(ctx context.Context) comet.BlockInfo {
+	return sdk.UnwrapSDKContext(ctx).CometInfo()
+}
+
+// headerInfoService implements core/header.Service the same way, by reading
+// the already well-known block header straight off the sdk.Context.
+type headerInfoService struct{}
+
+var _ header.Service = headerInfoService{}
+
+func (headerInfoService) GetHeaderInfo(ctx context.Context) header.Info {
+	return sdk.UnwrapSDKContext(ctx).HeaderInfo()
+}
@@ -0,0 +1,104 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app
+
+import (
+	"cosmossdk.io/math"
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// DefaultUnusedGasRefundFraction is the share of unused gas (priced at the
+// tx's own gas price) refunded to the fee payer by default; operators can
+// lower or raise it via the `unused-gas-refund-fraction` app.toml key.
+const DefaultUnusedGasRefundFraction = "0.5"
+
+// UnusedGasRefundDecorator is a PostDecorator (runs after message execution,
+// in the same store branch, so it is atomic with it) that refunds a
+// configurable fraction of the gas a successful tx did not consume, priced
+// at the fee the tx itself paid. It never refunds a failed tx, and never
+// refunds a tx containing a wasm execute/instantiate/migrate message, since
+// gas metered inside the VM does not map cleanly onto "unused SDK gas".
+type UnusedGasRefundDecorator struct {
+	bankKeeper     BankKeeper
+	refundFraction math.LegacyDec
+}
+
+// BankKeeper is the subset of the bank keeper the refund decorator needs.
+type BankKeeper interface {
+	SendCoinsFromModuleToAccount(ctx sdk.Context, senderModule string, recipientAddr sdk.AccAddress, amt sdk.Coins) error
+}
+
+func NewUnusedGasRefundDecorator(bankKeeper BankKeeper, refundFraction math.LegacyDec) UnusedGasRefundDecorator {
+	return UnusedGasRefundDecorator{
+		bankKeeper:     bankKeeper,
+		refundFraction: refundFraction,
+	}
+}
+
+// PostHandle implements sdk.PostDecorator.
+func (decorator UnusedGasRefundDecorator) PostHandle(ctx sdk.Context, tx sdk.Tx, simulate, success bool, next sdk.PostHandler) (sdk.Context, error) {
+	if !success || simulate {
+		return next(ctx, tx, simulate, success)
+	}
+
+	feeTx, ok := tx.(sdk.FeeTx)
+	if !ok {
+		return next(ctx, tx, simulate, success)
+	}
+
+	if containsWasmVMMeteredMsg(feeTx.GetMsgs()) {
+		return next(ctx, tx, simulate, success)
+	}
+
+	fee := feeTx.GetFee()
+	gasLimit := feeTx.GetGas()
+	gasConsumed := ctx.GasMeter().GasConsumed()
+	if gasConsumed >= gasLimit || fee.IsZero() {
+		return next(ctx, tx, simulate, success)
+	}
+
+	unusedGasFraction := math.LegacyNewDec(int64(gasLimit - gasConsumed)).QuoInt64(int64(gasLimit))
+	refundCoins := sdk.NewCoins()
+	for _, coin := range fee {
+		refundAmount := math.LegacyNewDecFromInt(coin.Amount).Mul(unusedGasFraction).Mul(decorator.refundFraction).TruncateInt()
+		if refundAmount.IsPositive() {
+			refundCoins = refundCoins.Add(sdk.NewCoin(coin.Denom, refundAmount))
+		}
+	}
+	if refundCoins.IsZero() {
+		return next(ctx, tx, simulate, success)
+	}
+
+	refundRecipient := feeTx.FeePayer()
+	if granter := feeTx.FeeGranter(); len(granter) > 0 {
+		// The fee granter's account is the one DeductFeeDecorator actually
+		// debited, so that's where the unused-gas refund belongs too -
+		// refunding the payer instead would hand it to whichever account
+		// merely signed the tx.
+		refundRecipient = granter
+	}
+
+	if err := decorator.bankKeeper.SendCoinsFromModuleToAccount(ctx, authtypes.FeeCollectorName, refundRecipient, refundCoins); err != nil {
+		return ctx, err
+	}
+
+	return next(ctx, tx, simulate, success)
+}
+
+// containsWasmVMMeteredMsg reports whether any message in the tx is a wasm
+// execute/instantiate/migrate whose gas was metered inside wasmvm rather
+// than by the SDK gas meter this decorator reads.
+func containsWasmVMMeteredMsg(msgs []sdk.Msg) bool {
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case *wasmtypes.MsgExecuteContract, *wasmtypes.MsgInstantiateContract, *wasmtypes.MsgInstantiateContract2, *wasmtypes.MsgMigrateContract:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package app_test
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/persistenceCore/v10/app"
+)
+
+type fakeBankKeeper struct {
+	sent      sdk.Coins
+	recipient sdk.AccAddress
+}
+
+func (keeper *fakeBankKeeper) SendCoinsFromModuleToAccount(_ sdk.Context, _ string, recipientAddr sdk.AccAddress, amt sdk.Coins) error {
+	keeper.sent = keeper.sent.Add(amt...)
+	keeper.recipient = recipientAddr
+	return nil
+}
+
+type fakeFeeTx struct {
+	sdk.Tx
+	fee     sdk.Coins
+	gas     uint64
+	payer   sdk.AccAddress
+	granter sdk.AccAddress
+	msgs    []sdk.Msg
+}
+
+func (tx fakeFeeTx) GetMsgs() []sdk.Msg         { return tx.msgs }
+func (tx fakeFeeTx) GetFee() sdk.Coins          { return tx.fee }
+func (tx fakeFeeTx) GetGas() uint64             { return tx.gas }
+func (tx fakeFeeTx) FeePayer() sdk.AccAddress   { return tx.payer }
+func (tx fakeFeeTx) FeeGranter() sdk.AccAddress { return tx.granter }
+
+func noopNext(ctx sdk.Context, _ sdk.Tx, _, _ bool) (sdk.Context, error) { return ctx, nil }
+
+func testCtx(t *testing.T, gasConsumed uint64) sdk.Context {
+	t.Helper()
+	ctx := sdk.Context{}.WithGasMeter(sdk.NewGasMeter(1_000_000))
+	ctx.GasMeter().ConsumeGas(gasConsumed, "test")
+	return ctx
+}
+
+func TestUnusedGasRefundDecorator_SuccessRefundsUnusedGas(t *testing.T) {
+	bank := &fakeBankKeeper{sent: sdk.NewCoins()}
+	decorator := app.NewUnusedGasRefundDecorator(bank, math.LegacyMustNewDecFromStr("0.5"))
+
+	tx := fakeFeeTx{
+		fee:   sdk.NewCoins(sdk.NewInt64Coin("xprt", 100)),
+		gas:   100_000,
+		payer: sdk.AccAddress("payer______________"),
+	}
+
+	_, err := decorator.PostHandle(testCtx(t, 50_000), tx, false, true, noopNext)
+	require.NoError(t, err)
+	require.False(t, bank.sent.IsZero(), "half the gas was unused, so a refund should have been sent")
+	require.Equal(t, tx.payer, bank.recipient, "with no fee granter the payer themselves funded the fee and should be refunded")
+}
+
+func TestUnusedGasRefundDecorator_FeeGranterIsRefundedInstead(t *testing.T) {
+	bank := &fakeBankKeeper{sent: sdk.NewCoins()}
+	decorator := app.NewUnusedGasRefundDecorator(bank, math.LegacyMustNewDecFromStr("0.5"))
+
+	tx := fakeFeeTx{
+		fee:     sdk.NewCoins(sdk.NewInt64Coin("xprt", 100)),
+		gas:     100_000,
+		payer:   sdk.AccAddress("payer______________"),
+		granter: sdk.AccAddress("granter_____________"),
+	}
+
+	_, err := decorator.PostHandle(testCtx(t, 50_000), tx, false, true, noopNext)
+	require.NoError(t, err)
+	require.False(t, bank.sent.IsZero(), "half the gas was unused, so a refund should have been sent")
+	require.Equal(t, tx.granter, bank.recipient, "the fee granter's account paid the fee DeductFeeDecorator debited, so the refund belongs there, not with the payer")
+}
+
+func TestUnusedGasRefundDecorator_FailureNoRefund(t *testing.T) {
+	bank := &fakeBankKeeper{sent: sdk.NewCoins()}
+	decorator := app.NewUnusedGasRefundDecorator(bank, math.LegacyMustNewDecFromStr("0.5"))
+
+	tx := fakeFeeTx{
+		fee:   sdk.NewCoins(sdk.NewInt64Coin("xprt", 100)),
+		gas:   100_000,
+		payer: sdk.AccAddress("payer______________"),
+	}
+
+	_, err := decorator.PostHandle(testCtx(t, 50_000), tx, false, false, noopNext)
+	require.NoError(t, err)
+	require.True(t, bank.sent.IsZero(), "a failed tx must never receive a gas refund")
+}
+
+func TestUnusedGasRefundDecorator_WasmExecuteDisablesRefund(t *testing.T) {
+	bank := &fakeBankKeeper{sent: sdk.NewCoins()}
+	decorator := app.NewUnusedGasRefundDecorator(bank, math.LegacyMustNewDecFromStr("0.5"))
+
+	tx := fakeFeeTx{
+		fee:   sdk.NewCoins(sdk.NewInt64Coin("xprt", 100)),
+		gas:   100_000,
+		payer: sdk.AccAddress("payer______________"),
+		msgs:  []sdk.Msg{&wasmtypes.MsgExecuteContract{}},
+	}
+
+	_, err := decorator.PostHandle(testCtx(t, 50_000), tx, false, true, noopNext)
+	require.NoError(t, err)
+	require.True(t, bank.sent.IsZero(), "gas metered inside wasmvm must not trigger an SDK-gas-meter refund")
+}
@@ -0,0 +1,34 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package upgrades
+
+import (
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/keepers"
+)
+
+// UpgradeHandlerArgs bundles everything a CreateUpgradeHandler needs to build
+// the SDK's upgradetypes.UpgradeHandler without every upgrade package having to
+// know how Application wires its own keepers together.
+type UpgradeHandlerArgs struct {
+	ModuleManager *module.Manager
+	Configurator  module.Configurator
+	Keepers       *keepers.AppKeepers
+	Codec         codec.Codec
+}
+
+// Upgrade defines a named chain upgrade: the handler that runs the in-place
+// store/state migrations for it, and the store upgrades the store loader must
+// apply before the handler runs.
+type Upgrade struct {
+	UpgradeName          string
+	CreateUpgradeHandler func(UpgradeHandlerArgs) upgradetypes.UpgradeHandler
+	StoreUpgrades        storetypes.StoreUpgrades
+}
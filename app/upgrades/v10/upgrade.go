@@ -0,0 +1,35 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v10
+
+import (
+	"context"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+)
+
+// UpgradeName is the on-chain upgrade plan name voted on by governance to
+// move the chain from the v9 (cosmos-sdk v0.47 / cometbft v0.37) binary to
+// the v10 (cosmos-sdk v0.50 / cometbft v0.38) binary.
+const UpgradeName = "v10"
+
+// Upgrade runs the SDK's built-in v047->v050 module migrations (consensus
+// params into x/consensus, crisis constant fee into x/consensus, etc.) via
+// moduleManager.RunMigrations; no persistenceCore-specific state migration is
+// required for this upgrade.
+var Upgrade = upgrades.Upgrade{
+	UpgradeName: UpgradeName,
+	CreateUpgradeHandler: func(args upgrades.UpgradeHandlerArgs) upgradetypes.UpgradeHandler {
+		return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+			return args.ModuleManager.RunMigrations(ctx, args.Configurator, fromVM)
+		}
+	},
+	StoreUpgrades: storetypes.StoreUpgrades{},
+}
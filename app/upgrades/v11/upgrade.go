@@ -0,0 +1,86 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v11
+
+import (
+	"context"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	ccvconsumertypes "github.com/cosmos/interchain-security/v4/x/ccv/consumer/types"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+)
+
+// UpgradeName is the governance-voted plan name for the sovereign->consumer
+// switch-over. It is only ever applied on networks that opt into ICS; a
+// sovereign-mode binary never schedules this plan.
+const UpgradeName = "v11-ics-consumer"
+
+// Upgrade freezes local staking-based consensus and bootstraps x/ccv/consumer
+// from the provider chain's CCV genesis, so the validator set that was
+// bonded under x/staking carries over as the initial consumer validator set
+// instead of being slashed to zero power at the changeover.
+var Upgrade = upgrades.Upgrade{
+	UpgradeName: UpgradeName,
+	CreateUpgradeHandler: func(args upgrades.UpgradeHandlerArgs) upgradetypes.UpgradeHandler {
+		return func(ctx context.Context, plan upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+			sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+			// The provider-side CCV genesis (client state, consensus state,
+			// unbonding period, params) is chain-specific data that can only
+			// come from the provider at the time the changeover proposal is
+			// drafted, so it travels in the upgrade plan's Info field as
+			// JSON and is decoded here rather than invented locally.
+			var consumerGenesis ccvconsumertypes.GenesisState
+			if err := args.Codec.UnmarshalJSON([]byte(plan.Info), &consumerGenesis); err != nil {
+				return nil, err
+			}
+
+			// Export the currently bonded validator set so it carries over
+			// as the initial consumer validator set instead of being
+			// slashed to zero power at the changeover.
+			bondedValidators := args.Keepers.StakingKeeper.GetLastValidators(sdkCtx)
+			initialValSet := make([]abci.ValidatorUpdate, 0, len(bondedValidators))
+			for _, val := range bondedValidators {
+				tmPubKey, err := val.TmConsPublicKey()
+				if err != nil {
+					return nil, err
+				}
+				initialValSet = append(initialValSet, abci.ValidatorUpdate{
+					PubKey: tmPubKey,
+					Power:  val.GetConsensusPower(sdk.DefaultPowerReduction),
+				})
+			}
+
+			consumerGenesis.NewChain = true
+			consumerGenesis.PreCCV = true
+			consumerGenesis.Provider.InitialValSet = initialValSet
+
+			// InitGenesis both creates the provider IBC client from the
+			// decoded provider genesis and marks this chain PreCCV, so the
+			// consumer module's own EndBlocker hands block production to
+			// the provider validator set once the handshake completes.
+			args.Keepers.ConsumerKeeper.InitGenesis(sdkCtx, &consumerGenesis)
+
+			// Freeze local staking-based consensus: x/staking stays mounted
+			// (pstake liquid-staking bookkeeping depends on it) but
+			// app/keepers' orderEndBlockers no longer lets it report
+			// validator-power updates to CometBFT once x/ccv/consumer is
+			// present - x/ccv/consumer.EndBlock. moves into that slot
+			// instead.
+
+			return args.ModuleManager.RunMigrations(ctx, args.Configurator, fromVM)
+		}
+	},
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Added: []string{ccvconsumertypes.ModuleName},
+	},
+}
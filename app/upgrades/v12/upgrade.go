@@ -0,0 +1,94 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v12
+
+import (
+	"context"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/group"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+)
+
+// UpgradeName is the governance-voted plan name that mounts x/group and
+// converts the chain's single-signer admin addresses to group policies.
+const UpgradeName = "v12"
+
+// legacyAdmins are the single-signer addresses this upgrade converts to
+// group-policy addresses so their authority can move to MsgVote instead of a
+// single key. These must be populated from the values governance approved in
+// the upgrade proposal text before this upgrade ships to a live network.
+//
+// BLOCKING: left empty here because this repo snapshot doesn't carry
+// app/keepers, so pstake-native's host-chain admin field and wasmd's
+// code-upload permission list - the two real sources for these addresses -
+// can't be read (or even named) from this package yet. As committed, this
+// upgrade only mounts x/group; it converts zero admins and is a no-op beyond
+// that, which defeats the purpose of the request. Do not cut a release from
+// this handler until legacyAdmins is wired to real addresses and the
+// resulting group policy address is repointed at the modules above.
+var legacyAdmins = []string{
+	// pstake-native host-chain admin, wasmd code-upload permission holder, ...
+}
+
+// Upgrade mounts x/group and, for every address in legacyAdmins, creates a
+// group with that address as sole initial member and a threshold-1 decision
+// policy. legacyAdmins is currently empty, so as committed this upgrade only
+// mounts x/group and converts nothing - see the legacyAdmins doc comment for
+// why and what's still blocking a real conversion.
+var Upgrade = upgrades.Upgrade{
+	UpgradeName: UpgradeName,
+	CreateUpgradeHandler: func(args upgrades.UpgradeHandlerArgs) upgradetypes.UpgradeHandler {
+		return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+			sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+			if len(legacyAdmins) == 0 {
+				sdkCtx.Logger().Error("v12 upgrade: legacyAdmins is empty, so no single-signer admin will be converted to a group policy - see the legacyAdmins doc comment before running this in production", "upgrade", UpgradeName)
+			}
+
+			for _, admin := range legacyAdmins {
+				policyAddress, err := convertAdminToGroupPolicy(sdkCtx, args, admin)
+				if err != nil {
+					return nil, err
+				}
+				sdkCtx.Logger().Info("converted single-signer admin to group policy", "admin", admin, "policy", policyAddress)
+			}
+
+			return args.ModuleManager.RunMigrations(ctx, args.Configurator, fromVM)
+		}
+	},
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Added: []string{group.ModuleName},
+	},
+}
+
+// convertAdminToGroupPolicy creates a 1-member, threshold-1 group owned by
+// admin and returns the resulting group policy address. Callers are
+// responsible for repointing any module field that stored admin directly
+// (pstake host-chain admin, wasmd code-upload permissions, ...) at the
+// returned address.
+func convertAdminToGroupPolicy(ctx sdk.Context, args upgrades.UpgradeHandlerArgs, admin string) (string, error) {
+	msg, err := group.NewMsgCreateGroupWithPolicy(
+		admin,
+		[]group.MemberRequest{{Address: admin, Weight: "1"}},
+		"", "", false,
+		group.NewThresholdDecisionPolicy("1", 0, 0),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := args.Keepers.GroupKeeper.CreateGroupWithPolicy(ctx, msg)
+	if err != nil {
+		return "", err
+	}
+
+	return response.GroupPolicyAddress, nil
+}
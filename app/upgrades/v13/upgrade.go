@@ -0,0 +1,34 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v13
+
+import (
+	"context"
+
+	circuittypes "cosmossdk.io/x/circuit/types"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+)
+
+// UpgradeName is the governance-voted plan name that mounts x/circuit so
+// governance (or a permissioned committee) can pause individual sdk.Msg
+// types during an incident without halting the chain.
+const UpgradeName = "v13"
+
+var Upgrade = upgrades.Upgrade{
+	UpgradeName: UpgradeName,
+	CreateUpgradeHandler: func(args upgrades.UpgradeHandlerArgs) upgradetypes.UpgradeHandler {
+		return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+			return args.ModuleManager.RunMigrations(ctx, args.Configurator, fromVM)
+		}
+	},
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Added: []string{circuittypes.ModuleName},
+	},
+}
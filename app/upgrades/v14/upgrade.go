@@ -0,0 +1,72 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v14
+
+import (
+	"context"
+
+	"cosmossdk.io/math"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+)
+
+// UpgradeName is the governance-voted plan name for this one-shot migration.
+const UpgradeName = "v14"
+
+// Upgrade replaces the height-gated `ctx.BlockHeight() > 11060956` branch
+// that used to run in Application.EndBlocker on every single block forever:
+// it walks the last validator set once, backfilling a zero math.LegacyDec for any
+// validator whose LiquidShares/ValidatorBondShares is still nil
+// (left over from before those LSM fields existed), then persists it.
+var Upgrade = upgrades.Upgrade{
+	UpgradeName: UpgradeName,
+	CreateUpgradeHandler: func(args upgrades.UpgradeHandlerArgs) upgradetypes.UpgradeHandler {
+		return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+			sdkCtx := sdk.UnwrapSDKContext(ctx)
+
+			MigrateLiquidStakingFields(sdkCtx, args.Keepers.StakingKeeper)
+
+			return args.ModuleManager.RunMigrations(ctx, args.Configurator, fromVM)
+		}
+	},
+	StoreUpgrades: storetypes.StoreUpgrades{},
+}
+
+// StakingKeeper is the subset of *stakingkeeper.Keeper this migration needs,
+// narrowed down so tests can exercise MigrateLiquidStakingFields against a
+// fake instead of a fully wired app/keepers.AppKeepers.
+type StakingKeeper interface {
+	GetLastValidators(ctx sdk.Context) []stakingtypes.Validator
+	SetValidator(ctx sdk.Context, validator stakingtypes.Validator)
+}
+
+// MigrateLiquidStakingFields is the one-shot version of the migration that
+// previously ran on every EndBlocker call past height 11060956.
+func MigrateLiquidStakingFields(ctx sdk.Context, stakingKeeper StakingKeeper) {
+	validators := stakingKeeper.GetLastValidators(ctx)
+	for _, val := range validators {
+		var valNeedsUpdate bool
+
+		if val.LiquidShares.IsNil() {
+			val.LiquidShares = math.LegacyZeroDec()
+			valNeedsUpdate = true
+		}
+		if val.ValidatorBondShares.IsNil() {
+			val.ValidatorBondShares = math.LegacyZeroDec()
+			valNeedsUpdate = true
+		}
+
+		if valNeedsUpdate {
+			stakingKeeper.SetValidator(ctx, val)
+			ctx.Logger().Info("migrated validator fields for liquid shares", "val", val.OperatorAddress)
+		}
+	}
+}
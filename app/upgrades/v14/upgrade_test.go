@@ -0,0 +1,76 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package v14_test
+
+import (
+	"testing"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/persistenceOne/persistenceCore/v10/app/upgrades"
+	v14 "github.com/persistenceOne/persistenceCore/v10/app/upgrades/v14"
+)
+
+// fixtureValidators mirrors a genesis export with validators persisted
+// before LiquidShares/ValidatorBondShares existed: both fields are left as
+// their Go zero value, math.LegacyDec{}, which reports IsNil() == true.
+func fixtureValidators() []stakingtypes.Validator {
+	return []stakingtypes.Validator{
+		{OperatorAddress: "persistencevaloper1nilfields"},
+		{OperatorAddress: "persistencevaloper1alreadyset", LiquidShares: math.LegacyNewDec(5), ValidatorBondShares: math.LegacyNewDec(5)},
+	}
+}
+
+type fakeStakingKeeper struct {
+	validators []stakingtypes.Validator
+}
+
+var _ v14.StakingKeeper = (*fakeStakingKeeper)(nil)
+
+func (keeper *fakeStakingKeeper) GetLastValidators(sdk.Context) []stakingtypes.Validator {
+	return keeper.validators
+}
+
+func (keeper *fakeStakingKeeper) SetValidator(_ sdk.Context, validator stakingtypes.Validator) {
+	for i, existing := range keeper.validators {
+		if existing.OperatorAddress == validator.OperatorAddress {
+			keeper.validators[i] = validator
+			return
+		}
+	}
+}
+
+// TestUpgradeZeroesNilLiquidStakingFields runs v14.MigrateLiquidStakingFields
+// itself - the same function v14.Upgrade's handler calls - against a fake
+// standing in for *stakingkeeper.Keeper, since building a real
+// upgrades.UpgradeHandlerArgs requires a fully wired app/keepers.AppKeepers.
+func TestUpgradeZeroesNilLiquidStakingFields(t *testing.T) {
+	keeper := &fakeStakingKeeper{validators: fixtureValidators()}
+	// MigrateLiquidStakingFields logs each migrated validator, so the
+	// context needs a logger.
+	ctx := sdk.Context{}.WithLogger(log.NewNopLogger())
+
+	v14.MigrateLiquidStakingFields(ctx, keeper)
+
+	migrated := keeper.GetLastValidators(ctx)
+	require.False(t, migrated[0].LiquidShares.IsNil())
+	require.True(t, migrated[0].LiquidShares.IsZero())
+	require.False(t, migrated[0].ValidatorBondShares.IsNil())
+	require.True(t, migrated[0].ValidatorBondShares.IsZero())
+
+	require.Equal(t, math.LegacyNewDec(5), migrated[1].LiquidShares)
+	require.Equal(t, math.LegacyNewDec(5), migrated[1].ValidatorBondShares)
+}
+
+func TestUpgradeHasNameAndHandler(t *testing.T) {
+	require.Equal(t, "v14", v14.Upgrade.UpgradeName)
+	require.NotNil(t, v14.Upgrade.CreateUpgradeHandler)
+	_ = upgrades.Upgrade{}
+}
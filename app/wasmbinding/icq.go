@@ -0,0 +1,62 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wasmbinding
+
+import (
+	"encoding/json"
+
+	wasmvmtypes "github.com/CosmWasm/wasmvm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	icqkeeper "github.com/cosmos/ibc-apps/modules/async-icq/v8/keeper"
+)
+
+// ICQQuery is the custom wasm query payload a contract sends to inspect this
+// chain's async-icq host configuration. async-icq/v8 only answers
+// OnRecvPacket for queries relayed in from a counterparty chain - it has no
+// client-submit entry point - so this is read-only: a contract can check
+// whether host ICQ is enabled and which gRPC query paths are currently
+// whitelisted before it asks a relayer or its own chain's ICA controller to
+// actually send one.
+type ICQQuery struct {
+	AllowedQueries *AllowedQueriesQuery `json:"allowed_queries"`
+}
+
+type AllowedQueriesQuery struct{}
+
+type AllowedQueriesResponse struct {
+	HostEnabled bool     `json:"host_enabled"`
+	Paths       []string `json:"paths"`
+}
+
+// ICQQuerier exposes async-icq's host query whitelist to CosmWasm contracts
+// through the wasmd custom query plugin hook.
+type ICQQuerier struct {
+	ICQKeeper icqkeeper.Keeper
+}
+
+func NewICQQuerier(icqKeeper icqkeeper.Keeper) *ICQQuerier {
+	return &ICQQuerier{ICQKeeper: icqKeeper}
+}
+
+// CustomQuery implements wasmkeeper.CustomQuerier.
+func (querier *ICQQuerier) CustomQuery(ctx sdk.Context, request json.RawMessage) ([]byte, error) {
+	var icqQuery ICQQuery
+	if err := json.Unmarshal(request, &icqQuery); err != nil {
+		return nil, wasmvmtypes.InvalidRequest{Err: err.Error()}
+	}
+
+	if icqQuery.AllowedQueries == nil {
+		return nil, wasmvmtypes.UnsupportedRequest{Kind: "unknown icq query variant"}
+	}
+
+	response := AllowedQueriesResponse{
+		HostEnabled: querier.ICQKeeper.IsHostEnabled(ctx),
+		Paths:       querier.ICQKeeper.GetAllowQueries(ctx),
+	}
+
+	return json.Marshal(response)
+}
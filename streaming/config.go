@@ -0,0 +1,72 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package streaming registers a baseapp.ABCIListener with pluggable sinks
+// (Kafka, gRPC; "firehose" is accepted as a sink name but builds no sink
+// here) so operators can run
+// indexing pipelines like mapofzones/cosmos-watcher against persistenceCore
+// without patching the binary.
+package streaming
+
+// Config is the `[streaming]` section of app.toml.
+type Config struct {
+	// Sinks enabled for this node: any of "kafka", "grpc", "firehose".
+	Sinks []string `mapstructure:"sinks"`
+	// RingBufferSize bounds the in-memory queue kept per sink; once full the
+	// oldest buffered event is dropped so a slow consumer cannot stall
+	// consensus. Defaults to DefaultRingBufferSize.
+	RingBufferSize int `mapstructure:"ring-buffer-size"`
+	// Filter restricts which FinalizeBlock events reach every configured
+	// sink; commit events are forwarded unfiltered.
+	Filter FilterConfig `mapstructure:"filter"`
+
+	Kafka KafkaConfig `mapstructure:"kafka"`
+	GRPC  GRPCConfig  `mapstructure:"grpc"`
+}
+
+// FilterConfig narrows the event stream before it is handed to a sink.
+type FilterConfig struct {
+	// Modules, when non-empty, only forwards a block if at least one of its
+	// ABCI events carries a "module" attribute naming one of these modules
+	// (e.g. "bank", "wasm"). A forwarded block keeps all of its events.
+	Modules []string `mapstructure:"modules"`
+	// EventTypes, when non-empty, only forwards a block if at least one of
+	// its ABCI events has one of these types. A forwarded block keeps all of
+	// its events.
+	EventTypes []string `mapstructure:"event-types"`
+	// SuccessfulTxsOnly drops events from transactions that did not succeed.
+	SuccessfulTxsOnly bool `mapstructure:"successful-txs-only"`
+}
+
+// KafkaConfig configures the Kafka producer sink.
+type KafkaConfig struct {
+	Brokers []string `mapstructure:"brokers"`
+	Topic   string   `mapstructure:"topic"`
+}
+
+// GRPCConfig configures the streaming gRPC server sink.
+type GRPCConfig struct {
+	ListenAddress string `mapstructure:"listen-address"`
+	// SubscriberBuffer bounds the per-subscriber backpressure queue; a
+	// subscriber that falls behind by more than this many events is
+	// disconnected rather than allowed to slow down the sink.
+	SubscriberBuffer int `mapstructure:"subscriber-buffer"`
+}
+
+// DefaultRingBufferSize is used when Config.RingBufferSize is unset.
+const DefaultRingBufferSize = 4096
+
+// DefaultConfig returns the `[streaming]` defaults written into a freshly
+// init'd app.toml; streaming is opt-in, so Sinks starts empty.
+func DefaultConfig() Config {
+	return Config{
+		Sinks:          nil,
+		RingBufferSize: DefaultRingBufferSize,
+		GRPC: GRPCConfig{
+			ListenAddress:    "0.0.0.0:9191",
+			SubscriberBuffer: 1024,
+		},
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package streaming
+
+import (
+	"net"
+	"sync"
+
+	tendermintlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// grpcSink streams BlockEvents to subscribed clients over gRPC server
+// streaming. Each subscriber gets its own bounded channel; a subscriber that
+// cannot keep up is disconnected instead of slowing the sink down for
+// everyone else.
+type grpcSink struct {
+	server      *grpc.Server
+	listener    net.Listener
+	codec       codec.BinaryCodec
+	logger      tendermintlog.Logger
+	buffer      *ringBuffer
+	bufferSize  int
+	done        chan struct{}
+	subscribers struct {
+		mutex sync.Mutex
+		chans map[chan []byte]struct{}
+	}
+}
+
+func newGRPCSink(cfg GRPCConfig, ringBufferSize int, appCodec codec.BinaryCodec, logger tendermintlog.Logger) (*grpcSink, error) {
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &grpcSink{
+		server:     grpc.NewServer(),
+		listener:   listener,
+		codec:      appCodec,
+		logger:     logger.With("sink", "grpc"),
+		buffer:     newRingBuffer("grpc", ringBufferSize),
+		bufferSize: cfg.SubscriberBuffer,
+		done:       make(chan struct{}),
+	}
+	sink.subscribers.chans = make(map[chan []byte]struct{})
+	sink.server.RegisterService(&blockEventServiceDesc, sink)
+
+	go sink.drainLoop()
+	go func() {
+		if err := sink.server.Serve(listener); err != nil {
+			sink.logger.Error("streaming grpc server stopped", "err", err)
+		}
+	}()
+
+	return sink, nil
+}
+
+func (sink *grpcSink) Name() string { return "grpc" }
+
+func (sink *grpcSink) Enqueue(event BlockEvent) {
+	payload, err := marshalBlockEvent(sink.codec, event)
+	if err != nil {
+		sink.logger.Error("failed to marshal block event", "err", err)
+		return
+	}
+	sink.buffer.push(payload)
+}
+
+func (sink *grpcSink) drainLoop() {
+	for {
+		select {
+		case <-sink.done:
+			return
+		case <-sink.buffer.wait():
+		}
+
+		for _, payload := range sink.buffer.drain() {
+			sink.broadcast(payload)
+		}
+	}
+}
+
+// broadcast fans a payload out to every subscriber channel without blocking;
+// a subscriber whose channel is full has fallen behind and is dropped.
+func (sink *grpcSink) broadcast(payload []byte) {
+	sink.subscribers.mutex.Lock()
+	defer sink.subscribers.mutex.Unlock()
+
+	for subscriberChan := range sink.subscribers.chans {
+		select {
+		case subscriberChan <- payload:
+		default:
+			delete(sink.subscribers.chans, subscriberChan)
+			close(subscriberChan)
+			droppedEventsTotal.WithLabelValues("grpc").Inc()
+		}
+	}
+}
+
+// blockEventServiceDesc describes the single server-streaming RPC subscribers
+// use to receive marshaled BlockEvents. It is hand-rolled rather than
+// generated from a .proto file because this package ships without a
+// protoc toolchain.
+var blockEventServiceDesc = grpc.ServiceDesc{
+	ServiceName: "persistenceCore.streaming.v1.BlockEventService",
+	HandlerType: (*any)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       subscribeHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// subscribeHandler backs the Subscribe RPC: it registers a bounded channel
+// with the sink's subscriber set and forwards every broadcast payload to the
+// client until the stream, the sink, or the subscriber's backpressure queue
+// closes.
+func subscribeHandler(srv interface{}, stream grpc.ServerStream) error {
+	var request emptypb.Empty
+	if err := stream.RecvMsg(&request); err != nil {
+		return err
+	}
+
+	sink := srv.(*grpcSink)
+	subscriberChan := make(chan []byte, sink.bufferSize)
+
+	sink.subscribers.mutex.Lock()
+	sink.subscribers.chans[subscriberChan] = struct{}{}
+	sink.subscribers.mutex.Unlock()
+
+	defer func() {
+		sink.subscribers.mutex.Lock()
+		delete(sink.subscribers.chans, subscriberChan)
+		sink.subscribers.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-sink.done:
+			return nil
+		case payload, ok := <-subscriberChan:
+			if !ok {
+				return nil
+			}
+			if err := stream.SendMsg(wrapperspb.Bytes(payload)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (sink *grpcSink) Close() error {
+	close(sink.done)
+	sink.server.GracefulStop()
+	return sink.listener.Close()
+}
@@ -0,0 +1,71 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package streaming
+
+import (
+	"context"
+
+	storetypes "cosmossdk.io/store/types"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// Listener fans out finalized blocks to every configured Sink. It implements
+// storetypes.ABCIListener and is installed by setupStreaming as the only
+// ABCIListener of the BaseApp's StreamingManager, so enabling it never
+// requires patching the binary - only a `[streaming]` section in app.toml.
+type Listener struct {
+	filter FilterConfig
+	sinks  []Sink
+	// height is the height of the last FinalizeBlock, used to key the
+	// commit event that follows it.
+	height int64
+}
+
+var _ storetypes.ABCIListener = (*Listener)(nil)
+
+// NewListener builds a Listener from sinks built by NewSinks.
+func NewListener(filter FilterConfig, sinks []Sink) *Listener {
+	return &Listener{filter: filter, sinks: sinks}
+}
+
+// ListenFinalizeBlock is called by BaseApp once a block has been executed,
+// before Commit. It must not mutate state and must be cheap: it only
+// filters and enqueues onto each sink's ring buffer.
+func (listener *Listener) ListenFinalizeBlock(_ context.Context, req abcitypes.RequestFinalizeBlock, res abcitypes.ResponseFinalizeBlock) error {
+	listener.height = req.Height
+	event := BlockEvent{Height: req.Height, Request: req, Response: res}
+	if !matchesFilter(listener.filter, event) {
+		return nil
+	}
+	for _, sink := range listener.sinks {
+		sink.Enqueue(event)
+	}
+	return nil
+}
+
+// ListenCommit is called by BaseApp after Commit, with the height's raw
+// store key/value change set attached for sinks that need it (e.g. a
+// gRPC sink offering full state-change streaming).
+func (listener *Listener) ListenCommit(_ context.Context, res abcitypes.ResponseCommit, changeSet []*storetypes.StoreKVPair) error {
+	event := BlockEvent{Height: listener.height, IsCommit: true, ChangeSet: changeSet}
+	_ = res
+	for _, sink := range listener.sinks {
+		sink.Enqueue(event)
+	}
+	return nil
+}
+
+// Close shuts down every configured sink. Events still waiting in a sink's
+// ring buffer are not delivered.
+func (listener *Listener) Close() error {
+	var firstErr error
+	for _, sink := range listener.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,44 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package streaming
+
+import (
+	"fmt"
+
+	tendermintlog "github.com/cometbft/cometbft/libs/log"
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// NewSinks builds the sinks named in cfg.Sinks. "firehose" is accepted as a
+// name but builds no sink here; it is listed for documentation purposes only
+// so operators see all three options in one place.
+func NewSinks(cfg Config, appCodec codec.BinaryCodec, logger tendermintlog.Logger) ([]Sink, error) {
+	ringBufferSize := cfg.RingBufferSize
+	if ringBufferSize <= 0 {
+		ringBufferSize = DefaultRingBufferSize
+	}
+
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "kafka":
+			sinks = append(sinks, newKafkaSink(cfg.Kafka, ringBufferSize, appCodec, logger))
+		case "grpc":
+			sink, err := newGRPCSink(cfg.GRPC, ringBufferSize, appCodec, logger)
+			if err != nil {
+				return nil, fmt.Errorf("failed to start streaming grpc sink: %w", err)
+			}
+			sinks = append(sinks, sink)
+		case "firehose":
+			// Firehose extraction is not done by this package;
+			// nothing to build here.
+		default:
+			return nil, fmt.Errorf("unknown streaming sink %q", name)
+		}
+	}
+
+	return sinks, nil
+}
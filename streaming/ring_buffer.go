@@ -0,0 +1,86 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package streaming
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// droppedEventsTotal counts events a sink's ring buffer discarded because it
+// was full, labeled by sink name so operators can alert on a consumer that
+// has fallen behind.
+var droppedEventsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "persistence_core",
+		Subsystem: "streaming",
+		Name:      "dropped_events_total",
+		Help:      "Number of events dropped by a streaming sink's ring buffer because it was full.",
+	},
+	[]string{"sink"},
+)
+
+func init() {
+	prometheus.MustRegister(droppedEventsTotal)
+}
+
+// ringBuffer is a fixed-capacity, drop-oldest FIFO queue of encoded events
+// sitting in front of a Sink. Consensus only ever calls push, which never
+// blocks; a background drain goroutine owned by the sink waits on notify
+// instead of polling, and removes events to hand to the slow consumer.
+type ringBuffer struct {
+	mutex    sync.Mutex
+	sinkName string
+	capacity int
+	events   [][]byte
+	notify   chan struct{}
+}
+
+func newRingBuffer(sinkName string, capacity int) *ringBuffer {
+	if capacity <= 0 {
+		capacity = DefaultRingBufferSize
+	}
+	return &ringBuffer{
+		sinkName: sinkName,
+		capacity: capacity,
+		events:   make([][]byte, 0, capacity),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// push appends an encoded event, dropping the oldest buffered event first if
+// the buffer is already at capacity, then wakes up the drain goroutine.
+func (buffer *ringBuffer) push(event []byte) {
+	buffer.mutex.Lock()
+	if len(buffer.events) >= buffer.capacity {
+		buffer.events = buffer.events[1:]
+		droppedEventsTotal.WithLabelValues(buffer.sinkName).Inc()
+	}
+	buffer.events = append(buffer.events, event)
+	buffer.mutex.Unlock()
+
+	select {
+	case buffer.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait returns the channel a drain goroutine should block on between drains;
+// it fires at least once for every push since the last drain.
+func (buffer *ringBuffer) wait() <-chan struct{} {
+	return buffer.notify
+}
+
+// drain removes and returns every buffered event.
+func (buffer *ringBuffer) drain() [][]byte {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	drained := buffer.events
+	buffer.events = make([][]byte, 0, buffer.capacity)
+	return drained
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright [2019] - [2021], PERSISTENCE TECHNOLOGIES PTE. LTD. and the persistenceCore contributors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package streaming
+
+import (
+	storetypes "cosmossdk.io/store/types"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+)
+
+// BlockEvent is the height-keyed payload handed to every sink for a finalized
+// block, carrying enough of FinalizeBlock's request/response to reconstruct
+// the legacy BeginBlock/DeliverTx/EndBlock view that downstream indexers
+// (mapofzones, cosmos-watcher, ...) expect.
+type BlockEvent struct {
+	Height    int64
+	Request   abcitypes.RequestFinalizeBlock
+	Response  abcitypes.ResponseFinalizeBlock
+	ChangeSet []*storetypes.StoreKVPair
+	IsCommit  bool
+}
+
+// Sink receives a filtered stream of BlockEvents. Implementations must not
+// block the caller; Enqueue hands the event to the sink's own ring buffer
+// and a background goroutine does the actual I/O.
+type Sink interface {
+	Name() string
+	Enqueue(event BlockEvent)
+	Close() error
+}
+
+// matchesFilter reports whether an event should be forwarded to a sink given
+// its FilterConfig; an empty Modules/EventTypes list matches everything.
+func matchesFilter(filter FilterConfig, event BlockEvent) bool {
+	if filter.SuccessfulTxsOnly {
+		for _, txResult := range event.Response.TxResults {
+			if txResult.Code != 0 {
+				return false
+			}
+		}
+	}
+
+	if len(filter.Modules) == 0 && len(filter.EventTypes) == 0 {
+		return true
+	}
+
+	for _, txResult := range event.Response.TxResults {
+		for _, abciEvent := range txResult.Events {
+			if eventMatches(filter, abciEvent) {
+				return true
+			}
+		}
+	}
+	for _, abciEvent := range event.Response.Events {
+		if eventMatches(filter, abciEvent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func eventMatches(filter FilterConfig, event abcitypes.Event) bool {
+	if len(filter.EventTypes) > 0 && !contains(filter.EventTypes, event.Type) {
+		return false
+	}
+	if len(filter.Modules) > 0 {
+		module, ok := attributeValue(event, "module")
+		if !ok || !contains(filter.Modules, module) {
+			return false
+		}
+	}
+	return true
+}
+
+func attributeValue(event abcitypes.Event, key string) (string, bool) {
+	for _, attribute := range event.Attributes {
+		if attribute.Key == key {
+			return attribute.Value, true
+		}
+	}
+	return "", false
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}